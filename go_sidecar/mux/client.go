@@ -0,0 +1,155 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a message to the peer.
+	writeWait = 10 * time.Second
+
+	// Time allowed to read the next pong message from the peer.
+	pongWait = 60 * time.Second
+
+	// Send pings to peer with this period. Must be less than pongWait.
+	pingPeriod = (pongWait * 9) / 10
+
+	// Maximum message size allowed from peer.
+	maxMessageSize = 8192
+
+	// Buffer size for a client's outbound message queue.
+	sendBufSize = 256
+
+	// wildcard matches any agent ID or message type in a subscription.
+	wildcard = "*"
+)
+
+// controlMessage is sent by a client over the WebSocket to narrow or
+// widen which messages it receives, e.g.
+// {"op":"subscribe","agents":["a1","a2"],"types":["stderr","event"]}
+type controlMessage struct {
+	Op     string   `json:"op"` // "subscribe" or "unsubscribe"
+	Agents []string `json:"agents,omitempty"`
+	Types  []string `json:"types,omitempty"`
+}
+
+// Client is a middleman between the Hub and a single WebSocket connection.
+type Client struct {
+	hub  *Hub
+	conn *websocket.Conn
+
+	// send is a buffered channel of outbound messages. Writes are owned
+	// exclusively by writePump; if it fills up the hub drops the client
+	// rather than blocking the broadcast for everyone else.
+	send chan Message
+
+	// sinceTime and sinceSeq scope the replay sent on registration, set
+	// from the `since` and `seq` query params on the /ws request.
+	sinceTime time.Time
+	sinceSeq  uint64
+
+	// agents and types are the client's subscription filters. Both
+	// default to {wildcard}, matching everything, until the client sends
+	// a "subscribe" control message narrowing them. Owned exclusively by
+	// the hub's run loop; readPump only ever mutates them indirectly via
+	// hub.subscribeReq.
+	agents map[string]bool
+	types  map[string]bool
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, sinceTime time.Time, sinceSeq uint64) *Client {
+	return &Client{
+		hub:       hub,
+		conn:      conn,
+		send:      make(chan Message, sendBufSize),
+		sinceTime: sinceTime,
+		sinceSeq:  sinceSeq,
+		agents:    map[string]bool{wildcard: true},
+		types:     map[string]bool{wildcard: true},
+	}
+}
+
+// matches reports whether msg passes the client's current subscription
+// filters.
+func (c *Client) matches(msg Message) bool {
+	if !c.agents[wildcard] && !c.agents[msg.AgentID] {
+		return false
+	}
+	if !c.types[wildcard] && !c.types[msg.Type] {
+		return false
+	}
+	return true
+}
+
+// writePump pumps messages from the send channel to the WebSocket
+// connection, pinging periodically to keep the connection alive.
+//
+// A goroutine running writePump is started for each connection. The
+// application ensures that there is at most one writer to a connection by
+// executing all writes from this goroutine.
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// The hub closed the channel.
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteJSON(msg); err != nil {
+				log.Printf("WS write error: %v", err)
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readPump pumps messages from the WebSocket connection to the hub.
+//
+// The application runs readPump in a per-connection goroutine. It ensures
+// that there is at most one reader on a connection by executing all reads
+// from this goroutine. It keeps the connection's read deadline alive via
+// pong handling, forwards subscribe/unsubscribe control messages to the
+// hub, and detects client disconnects.
+func (c *Client) readPump() {
+	defer func() {
+		c.hub.unregister <- c
+		c.conn.Close()
+	}()
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	for {
+		var ctrl controlMessage
+		if err := c.conn.ReadJSON(&ctrl); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("WS read error: %v", err)
+			}
+			break
+		}
+		switch ctrl.Op {
+		case "subscribe", "unsubscribe":
+			c.hub.subscribeReq <- subscriptionUpdate{client: c, op: ctrl.Op, agents: ctrl.Agents, types: ctrl.Types}
+		default:
+			log.Printf("WS unknown control op: %q", ctrl.Op)
+		}
+	}
+}
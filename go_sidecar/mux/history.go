@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// historyBuffer is a fixed-size in-memory ring buffer of recently
+// broadcast messages, used to replay missed traffic to reconnecting
+// dashboards.
+type historyBuffer struct {
+	mutex sync.Mutex
+	buf   []Message
+	size  int
+	next  int // index to write the next message into
+	full  bool
+}
+
+func newHistoryBuffer(size int) *historyBuffer {
+	return &historyBuffer{
+		buf:  make([]Message, size),
+		size: size,
+	}
+}
+
+// add appends msg to the buffer, overwriting the oldest entry once full.
+func (h *historyBuffer) add(msg Message) {
+	if h.size == 0 {
+		return
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.buf[h.next] = msg
+	h.next = (h.next + 1) % h.size
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// since returns buffered messages with Timestamp after t and Seq after
+// seq, in the order they were originally broadcast. Either filter may be
+// zero-valued to be ignored.
+func (h *historyBuffer) since(t time.Time, seq uint64) []Message {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var ordered []Message
+	if h.full {
+		ordered = append(ordered, h.buf[h.next:]...)
+		ordered = append(ordered, h.buf[:h.next]...)
+	} else {
+		ordered = append(ordered, h.buf[:h.next]...)
+	}
+
+	out := make([]Message, 0, len(ordered))
+	for _, msg := range ordered {
+		if msg.Seq <= seq {
+			continue
+		}
+		if !t.IsZero() && !msg.Timestamp.After(t) {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryBufferSince(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	msg := func(seq int, offset time.Duration) Message {
+		return Message{AgentID: "a1", Type: "stdout", Seq: uint64(seq), Timestamp: base.Add(offset)}
+	}
+
+	tests := []struct {
+		name     string
+		size     int
+		messages []Message // added in order
+		sinceT   time.Time
+		sinceSeq uint64
+		wantSeqs []uint64
+	}{
+		{
+			name:     "no filter returns everything in order",
+			size:     10,
+			messages: []Message{msg(1, 0), msg(2, time.Second), msg(3, 2 * time.Second)},
+			wantSeqs: []uint64{1, 2, 3},
+		},
+		{
+			name:     "seq filter excludes at and below the watermark",
+			size:     10,
+			messages: []Message{msg(1, 0), msg(2, time.Second), msg(3, 2 * time.Second)},
+			sinceSeq: 1,
+			wantSeqs: []uint64{2, 3},
+		},
+		{
+			name:     "time filter excludes at and before the watermark",
+			size:     10,
+			messages: []Message{msg(1, 0), msg(2, time.Second), msg(3, 2 * time.Second)},
+			sinceT:   base.Add(time.Second),
+			wantSeqs: []uint64{3},
+		},
+		{
+			name:     "wraps around once the ring buffer is full, oldest first",
+			size:     3,
+			messages: []Message{msg(1, 0), msg(2, time.Second), msg(3, 2 * time.Second), msg(4, 3 * time.Second)},
+			wantSeqs: []uint64{2, 3, 4}, // seq 1 was overwritten
+		},
+		{
+			name:     "zero size never retains anything",
+			size:     0,
+			messages: []Message{msg(1, 0)},
+			wantSeqs: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHistoryBuffer(tc.size)
+			for _, m := range tc.messages {
+				h.add(m)
+			}
+			got := h.since(tc.sinceT, tc.sinceSeq)
+			if len(got) != len(tc.wantSeqs) {
+				t.Fatalf("since() returned %d messages, want %d (%v)", len(got), len(tc.wantSeqs), got)
+			}
+			for i, m := range got {
+				if m.Seq != tc.wantSeqs[i] {
+					t.Errorf("message %d: got seq %d, want %d", i, m.Seq, tc.wantSeqs[i])
+				}
+			}
+		})
+	}
+}
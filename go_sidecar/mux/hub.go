@@ -0,0 +1,160 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// Message represents a log line or event from an agent
+type Message struct {
+	AgentID   string    `json:"agent_id"`
+	Type      string    `json:"type"` // stdout, stderr, event
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+	Seq       uint64    `json:"seq"`
+}
+
+// subscriptionUpdate narrows or widens a client's subscription filters,
+// sent over Hub.subscribeReq so the update is applied on the hub's run
+// loop instead of racing with broadcast dispatch.
+type subscriptionUpdate struct {
+	client *Client
+	op     string // "subscribe" or "unsubscribe"
+	agents []string
+	types  []string
+}
+
+// Hub maintains the set of active clients and broadcasts messages to them.
+type Hub struct {
+	clients      map[*Client]bool
+	broadcast    chan Message
+	register     chan *Client
+	unregister   chan *Client
+	subscribeReq chan subscriptionUpdate
+
+	history *historyBuffer
+	roster  *roster
+	sinks   []Sink
+	seq     uint64
+}
+
+func newHub(historySize int, sinks []Sink) *Hub {
+	return &Hub{
+		clients:      make(map[*Client]bool),
+		broadcast:    make(chan Message, 256), // Buffer slightly
+		register:     make(chan *Client),
+		unregister:   make(chan *Client),
+		subscribeReq: make(chan subscriptionUpdate),
+		history:      newHistoryBuffer(historySize),
+		roster:       newRoster(),
+		sinks:        sinks,
+	}
+}
+
+func (h *Hub) run() {
+	for {
+		select {
+		case client := <-h.register:
+			h.clients[client] = true
+			log.Println("New WebSocket client connected")
+			// Deliver replay here on the hub's own goroutine rather than a
+			// concurrent one: client.send can be closed by the unregister
+			// and buffer-full branches below, and a send on a closed
+			// channel panics even inside a select. Running replay on this
+			// loop serializes it with every path that closes send.
+			for _, msg := range h.history.since(client.sinceTime, client.sinceSeq) {
+				select {
+				case client.send <- msg:
+					continue
+				default:
+				}
+				log.Println("WS client send buffer full during replay, truncating")
+				break
+			}
+
+		case client := <-h.unregister:
+			if _, ok := h.clients[client]; ok {
+				delete(h.clients, client)
+				close(client.send)
+			}
+			log.Println("WebSocket client disconnected")
+
+		case update := <-h.subscribeReq:
+			applySubscriptionUpdate(update)
+
+		case msg := <-h.broadcast:
+			h.seq++
+			msg.Seq = h.seq
+			h.history.add(msg)
+			h.roster.observe(msg)
+			for _, sink := range h.sinks {
+				if err := sink.Write(msg); err != nil {
+					log.Printf("sink write error: %v", err)
+				}
+			}
+			for client := range h.clients {
+				if !client.matches(msg) {
+					continue
+				}
+				select {
+				case client.send <- msg:
+				default:
+					// Client isn't keeping up; drop it instead of
+					// blocking the broadcast for everyone else.
+					log.Println("WS client send buffer full, dropping client")
+					delete(h.clients, client)
+					close(client.send)
+				}
+			}
+		}
+	}
+}
+
+// applySubscriptionUpdate adds or removes the given agents/types from a
+// client's filters. Subscribing to the wildcard resets the filter to
+// match-everything; subscribing to specific entries turns off the
+// wildcard so only those entries match.
+//
+// unsubscribe has two edges worth spelling out:
+//   - While the filter is still the default/wildcard "match everything",
+//     unsubscribing named entries can't be expressed in this allow-list
+//     model (there's no way to say "everything except X"). It's a no-op,
+//     logged so it isn't mistaken for having taken effect.
+//   - Unsubscribing the last concrete entry leaves the filter empty,
+//     which matches nothing — the client receives no messages until it
+//     subscribes again. That's intentional "mute" behavior, not a bug.
+func applySubscriptionUpdate(u subscriptionUpdate) {
+	switch u.op {
+	case "subscribe":
+		updateFilter(u.client.agents, u.agents, true)
+		updateFilter(u.client.types, u.types, true)
+	case "unsubscribe":
+		updateFilter(u.client.agents, u.agents, false)
+		updateFilter(u.client.types, u.types, false)
+	}
+}
+
+func updateFilter(filter map[string]bool, entries []string, add bool) {
+	if len(entries) == 0 {
+		return
+	}
+	for _, e := range entries {
+		if add && e == wildcard {
+			for k := range filter {
+				delete(filter, k)
+			}
+			filter[wildcard] = true
+			return
+		}
+		if add {
+			delete(filter, wildcard)
+			filter[e] = true
+			continue
+		}
+		if filter[wildcard] {
+			log.Printf("WS unsubscribe %q ignored: client still matches wildcard %q", e, wildcard)
+			continue
+		}
+		delete(filter, e)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import "testing"
+
+func TestUpdateFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial map[string]bool
+		entries []string
+		add     bool
+		want    map[string]bool
+	}{
+		{
+			name:    "subscribe to specific entry turns off wildcard",
+			initial: map[string]bool{wildcard: true},
+			entries: []string{"a1"},
+			add:     true,
+			want:    map[string]bool{"a1": true},
+		},
+		{
+			name:    "subscribe to a second entry keeps the first",
+			initial: map[string]bool{"a1": true},
+			entries: []string{"a2"},
+			add:     true,
+			want:    map[string]bool{"a1": true, "a2": true},
+		},
+		{
+			name:    "subscribe to wildcard resets to match-everything",
+			initial: map[string]bool{"a1": true, "a2": true},
+			entries: []string{wildcard},
+			add:     true,
+			want:    map[string]bool{wildcard: true},
+		},
+		{
+			name:    "unsubscribe removes a concrete entry",
+			initial: map[string]bool{"a1": true, "a2": true},
+			entries: []string{"a1"},
+			add:     false,
+			want:    map[string]bool{"a2": true},
+		},
+		{
+			name:    "unsubscribe the last concrete entry mutes the client",
+			initial: map[string]bool{"a1": true},
+			entries: []string{"a1"},
+			add:     false,
+			want:    map[string]bool{},
+		},
+		{
+			name:    "unsubscribe while on default wildcard is a no-op",
+			initial: map[string]bool{wildcard: true},
+			entries: []string{"a1"},
+			add:     false,
+			want:    map[string]bool{wildcard: true},
+		},
+		{
+			name:    "no entries leaves the filter untouched",
+			initial: map[string]bool{"a1": true},
+			entries: nil,
+			add:     true,
+			want:    map[string]bool{"a1": true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := make(map[string]bool, len(tc.initial))
+			for k, v := range tc.initial {
+				filter[k] = v
+			}
+			updateFilter(filter, tc.entries, tc.add)
+			if len(filter) != len(tc.want) {
+				t.Fatalf("got %v, want %v", filter, tc.want)
+			}
+			for k, v := range tc.want {
+				if filter[k] != v {
+					t.Errorf("got %v, want %v", filter, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientMatches(t *testing.T) {
+	msg := Message{AgentID: "a1", Type: "stderr"}
+
+	tests := []struct {
+		name   string
+		agents map[string]bool
+		types  map[string]bool
+		want   bool
+	}{
+		{"default wildcard matches everything", map[string]bool{wildcard: true}, map[string]bool{wildcard: true}, true},
+		{"matching agent and type", map[string]bool{"a1": true}, map[string]bool{"stderr": true}, true},
+		{"non-matching agent", map[string]bool{"a2": true}, map[string]bool{wildcard: true}, false},
+		{"non-matching type", map[string]bool{wildcard: true}, map[string]bool{"stdout": true}, false},
+		{"empty agent filter matches nothing", map[string]bool{}, map[string]bool{wildcard: true}, false},
+		{"empty type filter matches nothing", map[string]bool{wildcard: true}, map[string]bool{}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Client{agents: tc.agents, types: tc.types}
+			if got := c.matches(msg); got != tc.want {
+				t.Errorf("matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+// sourceMeta identifies where an ingest stream came from, for logging.
+type sourceMeta struct {
+	Transport string // "unix", "tcp", "http"
+	Remote    string
+}
+
+// ingest reads line-delimited JSON Messages from r and forwards them to
+// the hub's broadcast channel. It's transport-agnostic: the Unix socket,
+// TCP listener, and HTTP /ingest endpoint all funnel through this.
+func ingest(r io.Reader, hub *Hub, meta sourceMeta) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			// If not JSON, wrap it as raw stdout
+			msg = Message{
+				AgentID:   "system",
+				Type:      "raw",
+				Content:   string(line),
+				Timestamp: time.Now(),
+			}
+		}
+		if msg.Timestamp.IsZero() {
+			msg.Timestamp = time.Now()
+		}
+		hub.broadcast <- msg
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("ingest error from %s %s: %v", meta.Transport, meta.Remote, err)
+	}
+}
+
+// authorizeTCP reads the shared-secret token as the connection's first
+// line and checks it against want. A blank want disables the check.
+func authorizeTCP(reader *bufio.Reader, want string) error {
+	if want == "" {
+		return nil
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading ingest token: %w", err)
+	}
+	got := strings.TrimSpace(line)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return errors.New("invalid ingest token")
+	}
+	return nil
+}
+
+// authorizeHTTP checks the shared-secret token against the Authorization
+// header, accepting either the bare token or "Bearer <token>". A blank
+// want disables the check.
+func authorizeHTTP(auth, want string) bool {
+	if want == "" {
+		return true
+	}
+	got := strings.TrimPrefix(auth, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// serveTCP accepts line-framed JSON ingest connections, optionally over
+// TLS and gated by a shared-secret token sent as the first line.
+func serveTCP(addr, tlsCert, tlsKey, token string, hub *Hub) {
+	var listener net.Listener
+	var err error
+	if tlsCert != "" && tlsKey != "" {
+		cert, cerr := tls.LoadX509KeyPair(tlsCert, tlsKey)
+		if cerr != nil {
+			log.Fatalf("Failed to load TLS cert/key: %v", cerr)
+		}
+		listener, err = tls.Listen("tcp", addr, &tls.Config{Certificates: []tls.Certificate{cert}})
+	} else {
+		listener, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		log.Fatalf("Failed to listen on tcp %s: %v", addr, err)
+	}
+	defer listener.Close()
+
+	log.Printf("Listening for agents on tcp %s", addr)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("TCP accept error: %v", err)
+			continue
+		}
+		go handleTCPConnection(conn, hub, token)
+	}
+}
+
+func handleTCPConnection(conn net.Conn, hub *Hub, token string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	if err := authorizeTCP(reader, token); err != nil {
+		log.Printf("TCP ingest rejected from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+	ingest(reader, hub, sourceMeta{Transport: "tcp", Remote: conn.RemoteAddr().String()})
+}
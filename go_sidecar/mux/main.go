@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"flag"
 	"log"
@@ -9,7 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"sync"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -17,99 +16,90 @@ import (
 )
 
 var (
-	socketPath = flag.String("socket", "/tmp/stravinsky.sock", "Path to Unix socket for agent input")
-	httpAddr   = flag.String("http", ":42000", "Address for HTTP/WebSocket server")
-	upgrader   = websocket.Upgrader{
+	socketPath  = flag.String("socket", "/tmp/stravinsky.sock", "Path to Unix socket for agent input")
+	httpAddr    = flag.String("http", ":42000", "Address for HTTP/WebSocket server")
+	historySize = flag.Int("history", 10000, "Number of recent messages to retain for replay")
+	tcpAddr     = flag.String("tcp", "", "Address for TCP line-framed JSON ingest (disabled if empty)")
+	tlsCert     = flag.String("tls-cert", "", "TLS certificate file for the TCP ingest listener")
+	tlsKey      = flag.String("tls-key", "", "TLS key file for the TCP ingest listener")
+	ingestToken = flag.String("ingest-token", "", "Shared secret required from TCP/HTTP ingest clients")
+	sinkSpecs   sinkFlags
+	upgrader    = websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool { return true }, // Allow all origins for local dev
 	}
 )
 
-// Message represents a log line or event from an agent
-type Message struct {
-	AgentID   string    `json:"agent_id"`
-	Type      string    `json:"type"` // stdout, stderr, event
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
+func init() {
+	flag.Var(&sinkSpecs, "sink", "Durable sink as <scheme>:<path>, e.g. jsonl:/var/log/stravinsky/ or sqlite:./stravinsky.db (repeatable)")
 }
 
-// Hub maintains the set of active WebSocket clients and broadcasts messages
-type Hub struct {
-	clients    map[*websocket.Conn]bool
-	broadcast  chan Message
-	register   chan *websocket.Conn
-	unregister chan *websocket.Conn
-	mutex      sync.Mutex
-}
-
-func newHub() *Hub {
-	return &Hub{
-		clients:    make(map[*websocket.Conn]bool),
-		broadcast:  make(chan Message, 256), // Buffer slightly
-		register:   make(chan *websocket.Conn),
-		unregister: make(chan *websocket.Conn),
+// parseSinkFilter reads the /search query params (agent, type, q, from,
+// to) into a SinkFilter.
+func parseSinkFilter(r *http.Request) SinkFilter {
+	q := r.URL.Query()
+	filter := SinkFilter{
+		AgentID: q.Get("agent"),
+		Type:    q.Get("type"),
+		Query:   q.Get("q"),
+	}
+	if v := q.Get("from"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filter.From = t
+		}
 	}
+	if v := q.Get("to"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filter.To = t
+		}
+	}
+	return filter
 }
 
-func (h *Hub) run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.mutex.Lock()
-			h.clients[client] = true
-			h.mutex.Unlock()
-			log.Println("New WebSocket client connected")
-
-		case client := <-h.unregister:
-			h.mutex.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				client.Close()
-			}
-			h.mutex.Unlock()
-			log.Println("WebSocket client disconnected")
-
-		case msg := <-h.broadcast:
-			h.mutex.Lock()
-			for client := range h.clients {
-				err := client.WriteJSON(msg)
-				if err != nil {
-					log.Printf("WS write error: %v", err)
-					client.Close()
-					delete(h.clients, client)
-				}
-			}
-			h.mutex.Unlock()
+// parseReplayParams reads the `since` (RFC3339 timestamp) and `seq`
+// (sequence number) query params used by /ws and /history to resume a
+// feed without duplicating already-seen messages.
+func parseReplayParams(r *http.Request) (sinceTime time.Time, sinceSeq uint64) {
+	if v := r.URL.Query().Get("since"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			sinceTime = t
+		}
+	}
+	if v := r.URL.Query().Get("seq"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			sinceSeq = n
 		}
 	}
+	return sinceTime, sinceSeq
 }
 
 func handleUnixConnection(conn net.Conn, hub *Hub) {
 	defer conn.Close()
-	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		var msg Message
-		if err := json.Unmarshal(line, &msg); err != nil {
-			// If not JSON, wrap it as raw stdout
-			msg = Message{
-				AgentID:   "system",
-				Type:      "raw",
-				Content:   string(line),
-				Timestamp: time.Now(),
-			}
-		}
-		if msg.Timestamp.IsZero() {
-			msg.Timestamp = time.Now()
-		}
-		hub.broadcast <- msg
-	}
+	ingest(conn, hub, sourceMeta{Transport: "unix", Remote: conn.RemoteAddr().String()})
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "tui" {
+		runTUI(os.Args[2:])
+		return
+	}
 	flag.Parse()
 
+	var sinks []Sink
+	for _, spec := range sinkSpecs {
+		sink, err := newSink(spec)
+		if err != nil {
+			log.Fatalf("Failed to open sink %q: %v", spec, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	defer func() {
+		for _, sink := range sinks {
+			sink.Close()
+		}
+	}()
+
 	// Setup Hub
-	hub := newHub()
+	hub := newHub(*historySize, sinks)
 	go hub.run()
 
 	// cleanup old socket
@@ -140,6 +130,11 @@ func main() {
 		}
 	}()
 
+	// Setup TCP Listener (Input from remote agents), if configured
+	if *tcpAddr != "" {
+		go serveTCP(*tcpAddr, *tlsCert, *tlsKey, *ingestToken, hub)
+	}
+
 	// Setup HTTP/WS Server (Output to Dashboard)
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		conn, err := upgrader.Upgrade(w, r, nil)
@@ -147,9 +142,75 @@ func main() {
 			log.Println("Upgrade error:", err)
 			return
 		}
-		hub.register <- conn
+		sinceTime, sinceSeq := parseReplayParams(r)
+		client := newClient(hub, conn, sinceTime, sinceSeq)
+		hub.register <- client
+		go client.writePump()
+		go client.readPump()
 	})
-	
+
+	http.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		sinceTime, sinceSeq := parseReplayParams(r)
+		w.Header().Set("Content-Type", "application/json")
+		if len(sinks) > 0 {
+			// Bound the response to the last `limit` matching rows
+			// (default historySize, same as the ring-buffer path)
+			// rather than streaming the entire persisted store;
+			// override with ?limit=N.
+			limit := *historySize
+			if v := r.URL.Query().Get("limit"); v != "" {
+				if n, err := strconv.Atoi(v); err == nil && n > 0 {
+					limit = n
+				}
+			}
+			msgs := make([]Message, 0, limit)
+			for msg := range sinks[0].Query(SinkFilter{From: sinceTime, Seq: sinceSeq}) {
+				msgs = append(msgs, msg)
+				if len(msgs) > limit {
+					msgs = msgs[len(msgs)-limit:]
+				}
+			}
+			json.NewEncoder(w).Encode(msgs)
+			return
+		}
+		json.NewEncoder(w).Encode(hub.history.since(sinceTime, sinceSeq))
+	})
+
+	http.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		if len(sinks) == 0 {
+			http.Error(w, "no sink configured", http.StatusNotImplemented)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for msg := range sinks[0].Query(parseSinkFilter(r)) {
+			if err := enc.Encode(msg); err != nil {
+				return
+			}
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		}
+	})
+
+	http.HandleFunc("/ingest", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorizeHTTP(r.Header.Get("Authorization"), *ingestToken) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ingest(r.Body, hub, sourceMeta{Transport: "http", Remote: r.RemoteAddr})
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	http.HandleFunc("/agents", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(hub.roster.list())
+	})
+
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
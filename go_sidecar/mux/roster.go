@@ -0,0 +1,51 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AgentInfo summarizes the traffic seen from a single agent, used to
+// build the /agents roster for dashboard picker UIs.
+type AgentInfo struct {
+	AgentID      string    `json:"agent_id"`
+	MessageCount uint64    `json:"message_count"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// roster tracks known agents as they're observed in broadcast traffic.
+// It's guarded by its own mutex rather than the hub's channels since it's
+// read far less often than the hub processes messages.
+type roster struct {
+	mutex sync.RWMutex
+	byID  map[string]*AgentInfo
+}
+
+func newRoster() *roster {
+	return &roster{byID: make(map[string]*AgentInfo)}
+}
+
+func (r *roster) observe(msg Message) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	info, ok := r.byID[msg.AgentID]
+	if !ok {
+		info = &AgentInfo{AgentID: msg.AgentID}
+		r.byID[msg.AgentID] = info
+	}
+	info.MessageCount++
+	info.LastSeen = msg.Timestamp
+}
+
+// list returns the known agents sorted by ID.
+func (r *roster) list() []AgentInfo {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	out := make([]AgentInfo, 0, len(r.byID))
+	for _, info := range r.byID {
+		out = append(out, *info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].AgentID < out[j].AgentID })
+	return out
+}
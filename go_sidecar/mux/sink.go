@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"log"
+	"strings"
+	"time"
+)
+
+// SinkFilter scopes a Sink.Query call. Zero-valued fields are ignored.
+type SinkFilter struct {
+	AgentID string
+	Type    string
+	Query   string // substring match against Content
+	From    time.Time // matches messages with Timestamp strictly after this
+	To      time.Time // matches messages with Timestamp at or before this
+	Seq     uint64 // matches messages with Seq strictly greater than this
+}
+
+func (f SinkFilter) matches(msg Message) bool {
+	if f.AgentID != "" && msg.AgentID != f.AgentID {
+		return false
+	}
+	if f.Type != "" && msg.Type != f.Type {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(msg.Content, f.Query) {
+		return false
+	}
+	if !f.From.IsZero() && !msg.Timestamp.After(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && msg.Timestamp.After(f.To) {
+		return false
+	}
+	if f.Seq != 0 && msg.Seq <= f.Seq {
+		return false
+	}
+	return true
+}
+
+// Sink durably stores broadcast messages and allows querying them back,
+// so /history and /search survive a stravinsky restart.
+type Sink interface {
+	Write(msg Message) error
+	Query(filter SinkFilter) iter.Seq[Message]
+	Close() error
+}
+
+// newSink builds a Sink from a "-sink" flag value of the form
+// "<scheme>:<path>", e.g. "jsonl:/var/log/stravinsky/" or
+// "sqlite:./stravinsky.db". The returned Sink writes asynchronously so a
+// slow disk or database never blocks the hub's broadcast loop.
+func newSink(spec string) (Sink, error) {
+	scheme, path, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid -sink %q, want <scheme>:<path>", spec)
+	}
+	var underlying Sink
+	var err error
+	switch scheme {
+	case "jsonl":
+		underlying, err = newJSONLSink(path)
+	case "sqlite":
+		underlying, err = newSQLiteSink(path)
+	default:
+		return nil, fmt.Errorf("unknown sink scheme %q", scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newAsyncSink(underlying), nil
+}
+
+// sinkWriteBufSize bounds how many writes an asyncSink will queue before
+// it starts dropping, mirroring the client send-buffer drop policy.
+const sinkWriteBufSize = 1024
+
+// asyncSink decouples writes to an underlying Sink from the caller (the
+// hub's single broadcast goroutine) via a buffered channel and its own
+// writer goroutine. A stalled disk or database blocks only this
+// goroutine, never client fan-out. Reads (Query) pass straight through
+// since they're already off the hot path.
+type asyncSink struct {
+	underlying Sink
+	writes     chan Message
+	done       chan struct{}
+}
+
+func newAsyncSink(underlying Sink) *asyncSink {
+	s := &asyncSink{
+		underlying: underlying,
+		writes:     make(chan Message, sinkWriteBufSize),
+		done:       make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *asyncSink) run() {
+	defer close(s.done)
+	for msg := range s.writes {
+		if err := s.underlying.Write(msg); err != nil {
+			log.Printf("sink write error: %v", err)
+		}
+	}
+}
+
+// Write never blocks: if the writer goroutine can't keep up, the
+// message is dropped and logged rather than stalling the caller.
+func (s *asyncSink) Write(msg Message) error {
+	select {
+	case s.writes <- msg:
+	default:
+		log.Println("sink write buffer full, dropping message")
+	}
+	return nil
+}
+
+func (s *asyncSink) Query(filter SinkFilter) iter.Seq[Message] {
+	return s.underlying.Query(filter)
+}
+
+func (s *asyncSink) Close() error {
+	close(s.writes)
+	<-s.done
+	return s.underlying.Close()
+}
+
+// sinkFlags collects repeated "-sink" flags into a slice; flag.Value's
+// single-assignment Set would otherwise only keep the last one.
+type sinkFlags []string
+
+func (s *sinkFlags) String() string { return strings.Join(*s, ",") }
+
+func (s *sinkFlags) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// flushInterval caps how long a written message can sit unflushed in the
+// JSONL sink's buffer, trading a small durability window for avoiding a
+// write syscall per message.
+const flushInterval = 1 * time.Second
+
+// JSONLSink appends each Message as a line of JSON to a file named after
+// the UTC date of the message's Timestamp, so each day's messages land in
+// their own file.
+type JSONLSink struct {
+	dir string
+
+	mutex  sync.Mutex
+	day    string
+	file   *os.File
+	writer *bufio.Writer
+
+	stopFlush chan struct{}
+}
+
+func newJSONLSink(dir string) (*JSONLSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("jsonl sink: %w", err)
+	}
+	s := &JSONLSink{dir: dir, stopFlush: make(chan struct{})}
+	go s.flushLoop()
+	return s, nil
+}
+
+// flushLoop periodically flushes the buffered writer so messages aren't
+// held indefinitely without a per-message flush syscall.
+func (s *JSONLSink) flushLoop() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mutex.Lock()
+			if s.writer != nil {
+				s.writer.Flush()
+			}
+			s.mutex.Unlock()
+		case <-s.stopFlush:
+			return
+		}
+	}
+}
+
+func (s *JSONLSink) pathFor(day string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("stravinsky-%s.jsonl", day))
+}
+
+// rotate switches to the file for day if it differs from the day of the
+// last write. Caller must hold s.mutex.
+func (s *JSONLSink) rotate(day string) error {
+	if s.file != nil && s.day == day {
+		return nil
+	}
+	if s.writer != nil {
+		s.writer.Flush()
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+	f, err := os.OpenFile(s.pathFor(day), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.writer = bufio.NewWriter(f)
+	s.day = day
+	return nil
+}
+
+// Write buffers msg as a JSON line; it's flushed by flushLoop rather than
+// on every call, so a burst of writes costs one syscall, not N.
+func (s *JSONLSink) Write(msg Message) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	day := msg.Timestamp.UTC().Format("2006-01-02")
+	if err := s.rotate(day); err != nil {
+		return fmt.Errorf("jsonl sink: %w", err)
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("jsonl sink: %w", err)
+	}
+	if _, err := s.writer.Write(line); err != nil {
+		return fmt.Errorf("jsonl sink: %w", err)
+	}
+	if err := s.writer.WriteByte('\n'); err != nil {
+		return fmt.Errorf("jsonl sink: %w", err)
+	}
+	return nil
+}
+
+// Query scans every daily file in the sink's directory and yields
+// messages matching filter, oldest first.
+func (s *JSONLSink) Query(filter SinkFilter) iter.Seq[Message] {
+	return func(yield func(Message) bool) {
+		entries, err := os.ReadDir(s.dir)
+		if err != nil {
+			return
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".jsonl" {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !s.queryFile(filepath.Join(s.dir, name), filter, yield) {
+				return
+			}
+		}
+	}
+}
+
+// queryFile streams one daily file, yielding matches. It returns false if
+// the caller should stop iterating entirely (the consumer broke early).
+func (s *JSONLSink) queryFile(path string, filter SinkFilter, yield func(Message) bool) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if !filter.matches(msg) {
+			continue
+		}
+		if !yield(msg) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *JSONLSink) Close() error {
+	close(s.stopFlush)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.writer != nil {
+		s.writer.Flush()
+	}
+	if s.file != nil {
+		return s.file.Close()
+	}
+	return nil
+}
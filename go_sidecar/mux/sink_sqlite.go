@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"iter"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS messages (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	agent_id TEXT NOT NULL,
+	type     TEXT NOT NULL,
+	content  TEXT NOT NULL,
+	ts       DATETIME NOT NULL,
+	seq      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_messages_agent_id ON messages(agent_id);
+CREATE INDEX IF NOT EXISTS idx_messages_ts ON messages(ts);
+CREATE INDEX IF NOT EXISTS idx_messages_seq ON messages(seq);
+`
+
+// SQLiteSink persists messages to a SQLite database for durable,
+// queryable storage across restarts.
+type SQLiteSink struct {
+	db *sql.DB
+}
+
+func newSQLiteSink(path string) (*SQLiteSink, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite sink: %w", err)
+	}
+	// WAL + NORMAL synchronous trade the durability of an fsync on every
+	// commit for batched checkpoint writes; acceptable for a log sink
+	// that's rebuilt from upstream agents, not a system of record.
+	for _, pragma := range []string{"PRAGMA journal_mode=WAL", "PRAGMA synchronous=NORMAL"} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("sqlite sink: %w", err)
+		}
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite sink: %w", err)
+	}
+	return &SQLiteSink{db: db}, nil
+}
+
+func (s *SQLiteSink) Write(msg Message) error {
+	_, err := s.db.Exec(
+		`INSERT INTO messages (agent_id, type, content, ts, seq) VALUES (?, ?, ?, ?, ?)`,
+		msg.AgentID, msg.Type, msg.Content, msg.Timestamp, msg.Seq,
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite sink: %w", err)
+	}
+	return nil
+}
+
+// Query streams rows matching filter, oldest first.
+func (s *SQLiteSink) Query(filter SinkFilter) iter.Seq[Message] {
+	return func(yield func(Message) bool) {
+		where := []string{"1=1"}
+		args := []any{}
+
+		if filter.AgentID != "" {
+			where = append(where, "agent_id = ?")
+			args = append(args, filter.AgentID)
+		}
+		if filter.Type != "" {
+			where = append(where, "type = ?")
+			args = append(args, filter.Type)
+		}
+		if filter.Query != "" {
+			where = append(where, "instr(content, ?) > 0")
+			args = append(args, filter.Query)
+		}
+		if !filter.From.IsZero() {
+			where = append(where, "ts > ?")
+			args = append(args, filter.From)
+		}
+		if !filter.To.IsZero() {
+			where = append(where, "ts <= ?")
+			args = append(args, filter.To)
+		}
+		if filter.Seq != 0 {
+			where = append(where, "seq > ?")
+			args = append(args, filter.Seq)
+		}
+
+		query := fmt.Sprintf(
+			`SELECT agent_id, type, content, ts, seq FROM messages WHERE %s ORDER BY ts ASC`,
+			strings.Join(where, " AND "),
+		)
+		rows, err := s.db.Query(query, args...)
+		if err != nil {
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var msg Message
+			if err := rows.Scan(&msg.AgentID, &msg.Type, &msg.Content, &msg.Timestamp, &msg.Seq); err != nil {
+				return
+			}
+			if !yield(msg) {
+				return
+			}
+		}
+	}
+}
+
+func (s *SQLiteSink) Close() error {
+	return s.db.Close()
+}
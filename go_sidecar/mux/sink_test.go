@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSinkFilterMatches(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	msg := Message{AgentID: "a1", Type: "stderr", Content: "boom", Timestamp: base, Seq: 5}
+
+	tests := []struct {
+		name   string
+		filter SinkFilter
+		want   bool
+	}{
+		{"zero filter matches everything", SinkFilter{}, true},
+		{"agent match", SinkFilter{AgentID: "a1"}, true},
+		{"agent mismatch", SinkFilter{AgentID: "a2"}, false},
+		{"type mismatch", SinkFilter{Type: "stdout"}, false},
+		{"content substring match", SinkFilter{Query: "oo"}, true},
+		{"content substring mismatch", SinkFilter{Query: "nope"}, false},
+		{"from excludes at the watermark", SinkFilter{From: base}, false},
+		{"from includes after the watermark", SinkFilter{From: base.Add(-time.Second)}, true},
+		{"to excludes after the watermark", SinkFilter{To: base.Add(-time.Second)}, false},
+		{"seq excludes at and below the watermark", SinkFilter{Seq: 5}, false},
+		{"seq includes above the watermark", SinkFilter{Seq: 4}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.matches(msg); got != tc.want {
+				t.Errorf("matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONLSinkWriteAndQuery(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := newJSONLSink(dir)
+	if err != nil {
+		t.Fatalf("newJSONLSink: %v", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := []Message{
+		{AgentID: "a1", Type: "stdout", Content: "hello", Timestamp: base, Seq: 1},
+		{AgentID: "a2", Type: "stderr", Content: "uh oh", Timestamp: base.Add(time.Second), Seq: 2},
+	}
+	for _, m := range want {
+		if err := sink.Write(m); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	sink.Close() // flush before reading back
+
+	var got []Message
+	for m := range sink.Query(SinkFilter{}) {
+		got = append(got, m)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Query returned %d messages, want %d: %v", len(got), len(want), got)
+	}
+	for i, m := range got {
+		if m.AgentID != want[i].AgentID || m.Seq != want[i].Seq {
+			t.Errorf("message %d: got %+v, want %+v", i, m, want[i])
+		}
+	}
+
+	var stderrOnly []Message
+	for m := range sink.Query(SinkFilter{Type: "stderr"}) {
+		stderrOnly = append(stderrOnly, m)
+	}
+	if len(stderrOnly) != 1 || stderrOnly[0].AgentID != "a2" {
+		t.Errorf("Query(Type=stderr) = %v, want just a2's message", stderrOnly)
+	}
+
+	var afterSeq1 []Message
+	for m := range sink.Query(SinkFilter{Seq: 1}) {
+		afterSeq1 = append(afterSeq1, m)
+	}
+	if len(afterSeq1) != 1 || afterSeq1[0].Seq != 2 {
+		t.Errorf("Query(Seq=1) = %v, want just seq 2", afterSeq1)
+	}
+
+	if _, err := filepath.Glob(filepath.Join(dir, "*.jsonl")); err != nil {
+		t.Fatalf("unexpected glob error: %v", err)
+	}
+}
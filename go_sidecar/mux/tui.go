@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorilla/websocket"
+)
+
+// maxAgentLog bounds how many messages are retained per agent in the TUI;
+// older entries are dropped once exceeded.
+const maxAgentLog = 2000
+
+var (
+	agentListStyle   = lipgloss.NewStyle().Width(28).Padding(0, 1).Border(lipgloss.NormalBorder(), false, true, false, false)
+	selectedRowStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	statusOKStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	statusStaleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	stdoutStyle      = lipgloss.NewStyle()
+	stderrStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	eventStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	helpStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+)
+
+// agentState tracks one agent's traffic as seen by the TUI.
+type agentState struct {
+	id       string
+	count    int
+	lastSeen time.Time
+	logs     []Message
+}
+
+type wsClosedMsg struct{ err error }
+
+// runTUI connects to a running stravinsky's /ws endpoint and renders a
+// live terminal dashboard. It's invoked as `stravinsky tui`.
+func runTUI(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	addr := fs.String("addr", "ws://localhost:42000/ws", "WebSocket address of the stravinsky server")
+	fs.Parse(args)
+
+	u, err := url.Parse(*addr)
+	if err != nil {
+		log.Fatalf("Invalid -addr: %v", err)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", u.String(), err)
+	}
+
+	m := newTUIModel(conn)
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		conn.Close()
+		log.Fatalf("TUI error: %v", err)
+	}
+	conn.Close()
+}
+
+type tuiModel struct {
+	conn  *websocket.Conn
+	msgCh chan Message
+
+	agents map[string]*agentState
+	order  []string
+
+	selected  int
+	filtering bool
+	filter    textinput.Model
+
+	paused bool
+	view   viewport.Model
+
+	width, height int
+}
+
+func newTUIModel(conn *websocket.Conn) *tuiModel {
+	ti := textinput.New()
+	ti.Placeholder = "filter agents..."
+	return &tuiModel{
+		conn:   conn,
+		msgCh:  make(chan Message, 256),
+		agents: make(map[string]*agentState),
+		filter: ti,
+		view:   viewport.New(0, 0),
+	}
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	go m.readLoop()
+	return waitForMessage(m.msgCh)
+}
+
+// readLoop decodes incoming WebSocket frames onto msgCh until the
+// connection closes, at which point it signals the program to quit.
+func (m *tuiModel) readLoop() {
+	for {
+		_, data, err := m.conn.ReadMessage()
+		if err != nil {
+			close(m.msgCh)
+			return
+		}
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		m.msgCh <- msg
+	}
+}
+
+func waitForMessage(ch chan Message) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return wsClosedMsg{}
+		}
+		return msg
+	}
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.view.Width = m.width - agentListStyle.GetWidth() - 4
+		m.view.Height = m.height - 3
+		return m, nil
+
+	case Message:
+		m.observe(msg)
+		return m, waitForMessage(m.msgCh)
+
+	case wsClosedMsg:
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *tuiModel) observe(msg Message) {
+	a, ok := m.agents[msg.AgentID]
+	if !ok {
+		a = &agentState{id: msg.AgentID}
+		m.agents[msg.AgentID] = a
+		m.order = append(m.order, msg.AgentID)
+		sort.Strings(m.order)
+	}
+	a.count++
+	a.lastSeen = msg.Timestamp
+	a.logs = append(a.logs, msg)
+	if len(a.logs) > maxAgentLog {
+		a.logs = a.logs[len(a.logs)-maxAgentLog:]
+	}
+
+	if m.selectedAgent() == msg.AgentID {
+		m.view.SetContent(renderLogs(a.logs))
+		if !m.paused {
+			m.view.GotoBottom()
+		}
+	}
+}
+
+func (m *tuiModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.filtering {
+		switch msg.String() {
+		case "enter", "esc":
+			m.filtering = false
+			m.filter.Blur()
+		default:
+			var cmd tea.Cmd
+			m.filter, cmd = m.filter.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "/":
+		m.filtering = true
+		m.filter.Focus()
+		return m, nil
+	case "p":
+		m.paused = !m.paused
+		return m, nil
+	case "s":
+		m.saveSelected()
+		return m, nil
+	case "up", "k":
+		m.moveSelection(-1)
+		return m, nil
+	case "down", "j":
+		m.moveSelection(1)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.view, cmd = m.view.Update(msg)
+	return m, cmd
+}
+
+// visibleAgents returns agent IDs in order, narrowed by the active
+// filter text.
+func (m *tuiModel) visibleAgents() []string {
+	q := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	if q == "" {
+		return m.order
+	}
+	var out []string
+	for _, id := range m.order {
+		if strings.Contains(strings.ToLower(id), q) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func (m *tuiModel) selectedAgent() string {
+	agents := m.visibleAgents()
+	if m.selected < 0 || m.selected >= len(agents) {
+		return ""
+	}
+	return agents[m.selected]
+}
+
+func (m *tuiModel) moveSelection(delta int) {
+	agents := m.visibleAgents()
+	if len(agents) == 0 {
+		return
+	}
+	m.selected = (m.selected + delta + len(agents)) % len(agents)
+	if a, ok := m.agents[m.selectedAgent()]; ok {
+		m.view.SetContent(renderLogs(a.logs))
+		m.view.GotoBottom()
+	}
+}
+
+// saveSelected writes the selected agent's current buffer to a file in
+// the working directory.
+func (m *tuiModel) saveSelected() {
+	id := m.selectedAgent()
+	a, ok := m.agents[id]
+	if !ok {
+		return
+	}
+	name := fmt.Sprintf("%s-%d.log", id, time.Now().Unix())
+	f, err := os.Create(name)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	for _, msg := range a.logs {
+		fmt.Fprintf(f, "[%s] %s: %s\n", msg.Timestamp.Format(time.RFC3339), msg.Type, msg.Content)
+	}
+}
+
+func renderLogs(logs []Message) string {
+	var b strings.Builder
+	for _, msg := range logs {
+		style := stdoutStyle
+		switch msg.Type {
+		case "stderr":
+			style = stderrStyle
+		case "event":
+			style = eventStyle
+		}
+		fmt.Fprintf(&b, "%s\n", style.Render(fmt.Sprintf("[%s] %s", msg.Type, msg.Content)))
+	}
+	return b.String()
+}
+
+func (m *tuiModel) View() string {
+	var list strings.Builder
+	agents := m.visibleAgents()
+	for i, id := range agents {
+		a := m.agents[id]
+		status := statusStaleStyle
+		if time.Since(a.lastSeen) < 5*time.Second {
+			status = statusOKStyle
+		}
+		row := fmt.Sprintf("%s %s (%d)", status.Render("●"), id, a.count)
+		if i == m.selected {
+			row = selectedRowStyle.Render(row)
+		}
+		list.WriteString(row + "\n")
+	}
+
+	left := agentListStyle.Height(m.height - 3).Render(list.String())
+	right := m.view.View()
+	body := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+
+	help := "↑/↓ select  /  filter  p pause  s save  q quit"
+	if m.paused {
+		help = "[paused] " + help
+	}
+	footer := helpStyle.Render(help)
+	if m.filtering {
+		footer = m.filter.View()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, body, footer)
+}